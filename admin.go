@@ -0,0 +1,187 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RunRecord describes a single invocation of a hook's command, kept around
+// for the admin API to inspect after the fact.
+type RunRecord struct {
+	ID          string    `json:"id"`
+	HookID      string    `json:"hook_id"`
+	Trigger     string    `json:"trigger"`
+	Matched     bool      `json:"matched"`
+	Status      string    `json:"status"`
+	ExitCode    int       `json:"exit_code"`
+	Stdout      string    `json:"stdout"`
+	Stderr      string    `json:"stderr"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	DurationSec float64   `json:"duration_seconds"`
+}
+
+// runHistory is a fixed-capacity ring buffer of RunRecords per hook ID,
+// guarded by a mutex so it can be written from the handleHook goroutines
+// and read from the admin API concurrently. A capacity <= 0 means history
+// is disabled: add becomes a no-op instead of growing the buffer
+// unbounded, so -history 0 actually minimizes memory use like an operator
+// passing it would expect.
+type runHistory struct {
+	mu       sync.Mutex
+	capacity int
+	byHook   map[string][]*RunRecord
+	byRunID  map[string]*RunRecord
+	seq      uint64
+}
+
+func newRunHistory(capacity int) *runHistory {
+	return &runHistory{
+		capacity: capacity,
+		byHook:   make(map[string][]*RunRecord),
+		byRunID:  make(map[string]*RunRecord),
+	}
+}
+
+// add appends rec to the hook's ring buffer, evicting the oldest record
+// once capacity is exceeded, and assigns it a run ID if it doesn't have
+// one. It's a no-op when history is disabled (capacity <= 0).
+func (h *runHistory) add(rec *RunRecord) {
+	if h.capacity <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if rec.ID == "" {
+		h.seq++
+		rec.ID = fmt.Sprintf("%s-%d", rec.HookID, h.seq)
+	}
+
+	records := append(h.byHook[rec.HookID], rec)
+	if len(records) > h.capacity {
+		evicted := records[0]
+		delete(h.byRunID, evicted.ID)
+		records = records[len(records)-h.capacity:]
+	}
+	h.byHook[rec.HookID] = records
+	h.byRunID[rec.ID] = rec
+}
+
+func (h *runHistory) forHook(id string) []*RunRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]*RunRecord(nil), h.byHook[id]...)
+}
+
+func (h *runHistory) byID(runID string) *RunRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.byRunID[runID]
+}
+
+// newAdminRouter builds the admin sub-router exposing hook inspection,
+// manual reload, and run history endpoints. It is mounted either under
+// /admin on the main router or served standalone on -admin-listen.
+func newAdminRouter() *mux.Router {
+	r := mux.NewRouter()
+
+	r.HandleFunc("/admin/hooks", adminListHooks).Methods("GET")
+	r.HandleFunc("/admin/hooks/{id}", adminGetHook).Methods("GET")
+	r.HandleFunc("/admin/reload", adminReload).Methods("POST")
+	r.HandleFunc("/admin/hooks/{id}/runs", adminListRuns).Methods("GET")
+	r.HandleFunc("/admin/runs/{run-id}", adminGetRun).Methods("GET")
+
+	if *adminToken != "" {
+		r.Use(adminAuthMiddleware)
+	}
+
+	return r
+}
+
+// adminAuthMiddleware requires a matching "Authorization: Bearer <token>"
+// header on every admin request when -admin-token is set.
+func adminAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, prefix) || subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(*adminToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, "Unauthorized.")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func adminListHooks(w http.ResponseWriter, r *http.Request) {
+	ids := make([]string, 0, len(hooks))
+	for _, h := range hooks {
+		ids = append(ids, h.ID)
+	}
+
+	writeJSON(w, http.StatusOK, ids)
+}
+
+func adminGetHook(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	h := hooks.Match(id)
+	if h == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "Hook not found.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h)
+}
+
+func adminReload(w http.ResponseWriter, r *http.Request) {
+	reloadHooks()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"reloaded": true,
+		"count":    len(hooks),
+	})
+}
+
+func adminListRuns(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	writeJSON(w, http.StatusOK, history.forHook(id))
+}
+
+func adminGetRun(w http.ResponseWriter, r *http.Request) {
+	runID := mux.Vars(r)["run-id"]
+
+	rec := history.byID(runID)
+	if rec == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "Run not found.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rec)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("error encoding admin API response: %+v\n", err)
+	}
+}
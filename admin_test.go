@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestRunHistoryEvictsOldestRecord(t *testing.T) {
+	h := newRunHistory(2)
+
+	h.add(&RunRecord{HookID: "h1"})
+	h.add(&RunRecord{HookID: "h1"})
+	h.add(&RunRecord{HookID: "h1"})
+
+	records := h.forHook("h1")
+	if len(records) != 2 {
+		t.Fatalf("forHook returned %d records, want 2", len(records))
+	}
+	if records[0].ID == "h1-1" {
+		t.Error("oldest record was not evicted")
+	}
+}
+
+func TestRunHistoryDisabledAtZeroCapacity(t *testing.T) {
+	h := newRunHistory(0)
+
+	h.add(&RunRecord{HookID: "h1"})
+
+	if records := h.forHook("h1"); len(records) != 0 {
+		t.Errorf("forHook returned %v with capacity 0, want none recorded", records)
+	}
+}
+
+func TestRunHistoryAssignsRunID(t *testing.T) {
+	h := newRunHistory(5)
+
+	rec := &RunRecord{HookID: "h1"}
+	h.add(rec)
+
+	if rec.ID == "" {
+		t.Error("add left rec.ID empty")
+	}
+	if h.byID(rec.ID) != rec {
+		t.Errorf("byID(%q) did not return the added record", rec.ID)
+	}
+}
@@ -0,0 +1,45 @@
+// Package auth verifies that an incoming request really came from the
+// provider a hook claims to be wired to, before the hook's trigger rule is
+// ever evaluated. It replaces hand-rolled trigger rules that compare a
+// signature header byte-for-byte with purpose-built verifiers for GitHub,
+// GitLab, and Bitbucket, plus a generic HMAC/Ed25519 scheme for anything
+// else. Third parties can register additional provider types with
+// Register.
+package auth
+
+import "net/http"
+
+// Request is the normalized input to a Verifier: the raw headers and body
+// exactly as received, since signatures are computed over the exact bytes
+// of the request.
+type Request struct {
+	Headers    http.Header
+	Body       []byte
+	RemoteAddr string
+}
+
+// Verifier checks a single request against one provider's signing or
+// allowlist scheme, returning a non-nil error if verification fails.
+type Verifier interface {
+	Verify(req *Request) error
+}
+
+// Spec is the `auth` block attached to a hook in the hooks file. It isn't
+// part of the upstream hook.Hook struct, so it's parsed out of the same
+// file separately by LoadHookSpecs, the same way executor.Spec is.
+type Spec struct {
+	Type string `json:"type"` // github, gitlab, bitbucket, generic, or a registered third-party type
+
+	// secret source, shared by github, gitlab, and generic: exactly one
+	// of these should be set, checked in this order.
+	SecretEnv  string `json:"secret_env,omitempty"`
+	SecretFile string `json:"secret_file,omitempty"`
+	Secret     string `json:"secret,omitempty"`
+
+	// generic
+	Header    string `json:"header,omitempty"`    // header carrying the signature
+	Algorithm string `json:"algorithm,omitempty"` // hmac-sha256 (default) or ed25519; for ed25519, Secret holds the base64 public key
+
+	// bitbucket
+	AllowedIPs []string `json:"allowed_ips,omitempty"`
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+)
+
+// bitbucketVerifier checks that the request's source IP falls within the
+// hook's configured allowlist, since Bitbucket Cloud webhooks don't sign
+// requests and instead document a fixed set of outbound IP ranges.
+type bitbucketVerifier struct {
+	spec *Spec
+	nets []*net.IPNet
+}
+
+func newBitbucketVerifier(spec *Spec) Verifier {
+	v := &bitbucketVerifier{spec: spec}
+
+	for _, entry := range spec.AllowedIPs {
+		cidr := entry
+		if net.ParseIP(cidr) != nil {
+			// bare IP - treat it as a /32 (or /128) allowlist entry
+			if ip4 := net.ParseIP(cidr).To4(); ip4 != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+			v.nets = append(v.nets, ipNet)
+		}
+	}
+
+	return v
+}
+
+func (v *bitbucketVerifier) Verify(req *Request) error {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("couldn't parse remote address %q", req.RemoteAddr)
+	}
+
+	for _, ipNet := range v.nets {
+		if ipNet.Contains(ip) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("remote address %s is not in the allowed IP ranges", ip)
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// genericVerifier covers providers without a purpose-built verifier: an
+// HMAC-SHA256 signature (the default) or an Ed25519 signature, read from a
+// configurable header.
+type genericVerifier struct {
+	spec *Spec
+}
+
+func newGenericVerifier(spec *Spec) Verifier {
+	return &genericVerifier{spec: spec}
+}
+
+func (v *genericVerifier) Verify(req *Request) error {
+	header := v.spec.Header
+	if header == "" {
+		return fmt.Errorf("generic auth requires a header to read the signature from")
+	}
+
+	sig := req.Headers.Get(header)
+	if sig == "" {
+		return fmt.Errorf("missing %s header", header)
+	}
+
+	switch v.spec.Algorithm {
+	case "ed25519":
+		return v.verifyEd25519(req.Body, sig)
+	case "", "hmac-sha256":
+		return v.verifyHMAC(req.Body, sig)
+	default:
+		return fmt.Errorf("unsupported generic auth algorithm %q", v.spec.Algorithm)
+	}
+}
+
+func (v *genericVerifier) verifyHMAC(body []byte, sig string) error {
+	secret, err := resolveSecret(v.spec)
+	if err != nil {
+		return err
+	}
+
+	want, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("malformed signature header")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	if !hmac.Equal(mac.Sum(nil), want) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
+
+// verifyEd25519 checks sig against body using the spec's public key, which
+// for this algorithm is a base64-encoded Ed25519 public key carried in the
+// Secret field rather than a shared HMAC secret.
+func (v *genericVerifier) verifyEd25519(body []byte, sig string) error {
+	if v.spec.Secret == "" {
+		return fmt.Errorf("ed25519 auth requires a base64 public key in secret")
+	}
+
+	pubKey, err := base64.StdEncoding.DecodeString(v.spec.Secret)
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("malformed ed25519 public key")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("malformed signature header")
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(pubKey), body, signature) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+)
+
+func TestGenericVerifierHMAC(t *testing.T) {
+	const (
+		secret = "genericsecret"
+		header = "X-Signature"
+	)
+	body := []byte(`{"event":"push"}`)
+
+	sign := func(key string) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	verifier, err := For(&Spec{Type: "generic", Secret: secret, Header: header})
+	if err != nil {
+		t.Fatalf("For: %+v", err)
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set(header, sign(secret))
+
+		if err := verifier.Verify(&Request{Headers: headers, Body: body}); err != nil {
+			t.Errorf("Verify returned %+v, want nil", err)
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set(header, sign("wrongsecret"))
+
+		if err := verifier.Verify(&Request{Headers: headers, Body: body}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+
+	t.Run("no header configured", func(t *testing.T) {
+		verifier, err := For(&Spec{Type: "generic", Secret: secret})
+		if err != nil {
+			t.Fatalf("For: %+v", err)
+		}
+
+		headers := http.Header{}
+		headers.Set(header, sign(secret))
+
+		if err := verifier.Verify(&Request{Headers: headers, Body: body}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+}
+
+func TestGenericVerifierEd25519(t *testing.T) {
+	const header = "X-Signature"
+	body := []byte(`{"event":"push"}`)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %+v", err)
+	}
+
+	verifier, err := For(&Spec{
+		Type:      "generic",
+		Algorithm: "ed25519",
+		Secret:    base64.StdEncoding.EncodeToString(pub),
+		Header:    header,
+	})
+	if err != nil {
+		t.Fatalf("For: %+v", err)
+	}
+
+	sign := func(key ed25519.PrivateKey, msg []byte) string {
+		return base64.StdEncoding.EncodeToString(ed25519.Sign(key, msg))
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set(header, sign(priv, body))
+
+		if err := verifier.Verify(&Request{Headers: headers, Body: body}); err != nil {
+			t.Errorf("Verify returned %+v, want nil", err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("GenerateKey: %+v", err)
+		}
+
+		headers := http.Header{}
+		headers.Set(header, sign(otherPriv, body))
+
+		if err := verifier.Verify(&Request{Headers: headers, Body: body}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set(header, sign(priv, body))
+
+		if err := verifier.Verify(&Request{Headers: headers, Body: []byte(`{"event":"evil"}`)}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// githubVerifier checks GitHub's X-Hub-Signature-256 header: an HMAC-SHA256
+// of the raw request body, hex-encoded and prefixed with "sha256=".
+type githubVerifier struct {
+	spec *Spec
+}
+
+func newGitHubVerifier(spec *Spec) Verifier {
+	return &githubVerifier{spec: spec}
+}
+
+func (v *githubVerifier) Verify(req *Request) error {
+	secret, err := resolveSecret(v.spec)
+	if err != nil {
+		return err
+	}
+
+	header := req.Headers.Get("X-Hub-Signature-256")
+	if header == "" {
+		return fmt.Errorf("missing X-Hub-Signature-256 header")
+	}
+
+	sig := strings.TrimPrefix(header, "sha256=")
+	want, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("malformed X-Hub-Signature-256 header")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(req.Body)
+	got := mac.Sum(nil)
+
+	if !hmac.Equal(got, want) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+)
+
+func githubSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGitHubVerifier(t *testing.T) {
+	const secret = "topsecret"
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	verifier, err := For(&Spec{Type: "github", Secret: secret})
+	if err != nil {
+		t.Fatalf("For: %+v", err)
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Hub-Signature-256", githubSignature(secret, body))
+
+		if err := verifier.Verify(&Request{Headers: headers, Body: body}); err != nil {
+			t.Errorf("Verify returned %+v, want nil", err)
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Hub-Signature-256", githubSignature("wrongsecret", body))
+
+		if err := verifier.Verify(&Request{Headers: headers, Body: body}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Hub-Signature-256", githubSignature(secret, body))
+
+		tampered := []byte(`{"ref":"refs/heads/evil"}`)
+		if err := verifier.Verify(&Request{Headers: headers, Body: tampered}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		if err := verifier.Verify(&Request{Headers: http.Header{}, Body: body}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// gitlabVerifier checks GitLab's X-Gitlab-Token header, which carries the
+// shared secret verbatim rather than a signature over the body.
+type gitlabVerifier struct {
+	spec *Spec
+}
+
+func newGitLabVerifier(spec *Spec) Verifier {
+	return &gitlabVerifier{spec: spec}
+}
+
+func (v *gitlabVerifier) Verify(req *Request) error {
+	secret, err := resolveSecret(v.spec)
+	if err != nil {
+		return err
+	}
+
+	token := req.Headers.Get("X-Gitlab-Token")
+	if token == "" {
+		return fmt.Errorf("missing X-Gitlab-Token header")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return fmt.Errorf("token mismatch")
+	}
+
+	return nil
+}
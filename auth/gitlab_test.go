@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGitLabVerifier(t *testing.T) {
+	const secret = "glsecret"
+
+	verifier, err := For(&Spec{Type: "gitlab", Secret: secret})
+	if err != nil {
+		t.Fatalf("For: %+v", err)
+	}
+
+	t.Run("matching token", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Gitlab-Token", secret)
+
+		if err := verifier.Verify(&Request{Headers: headers}); err != nil {
+			t.Errorf("Verify returned %+v, want nil", err)
+		}
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Gitlab-Token", "not-the-secret")
+
+		if err := verifier.Verify(&Request{Headers: headers}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		if err := verifier.Verify(&Request{Headers: http.Header{}}); err == nil {
+			t.Error("Verify returned nil, want an error")
+		}
+	})
+}
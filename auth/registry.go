@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Factory builds a Verifier from its configured Spec.
+type Factory func(spec *Spec) Verifier
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Factory{}
+)
+
+// Register adds a constructor for an auth type, so third parties can plug
+// in new provider schemes without modifying this package. Calling Register
+// with a type that's already registered replaces it.
+func Register(typ string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[typ] = factory
+}
+
+// For resolves the Verifier for a hook's auth spec. It returns a nil
+// Verifier and nil error when spec is nil, meaning the hook has no auth
+// block configured and should skip verification entirely. An unrecognized
+// type is an error rather than a silent pass, since failing open on a
+// misconfigured hook would defeat the point of this package.
+func For(spec *Spec) (Verifier, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	registryMu.Lock()
+	factory, ok := registry[spec.Type]
+	registryMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no verifier registered for auth type %q", spec.Type)
+	}
+
+	return factory(spec), nil
+}
+
+func init() {
+	Register("github", newGitHubVerifier)
+	Register("gitlab", newGitLabVerifier)
+	Register("bitbucket", newBitbucketVerifier)
+	Register("generic", newGenericVerifier)
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// resolveSecret reads a spec's configured secret from whichever source it
+// names, checked in order: an environment variable, a file, or a literal
+// value in the hooks file.
+func resolveSecret(spec *Spec) (string, error) {
+	switch {
+	case spec.SecretEnv != "":
+		v, ok := os.LookupEnv(spec.SecretEnv)
+		if !ok {
+			return "", fmt.Errorf("secret_env %q is not set", spec.SecretEnv)
+		}
+		return v, nil
+	case spec.SecretFile != "":
+		data, err := ioutil.ReadFile(spec.SecretFile)
+		if err != nil {
+			return "", fmt.Errorf("reading secret_file: %+v", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case spec.Secret != "":
+		return spec.Secret, nil
+	default:
+		return "", fmt.Errorf("no secret configured (set secret_env, secret_file, or secret)")
+	}
+}
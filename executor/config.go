@@ -0,0 +1,42 @@
+package executor
+
+import (
+	"encoding/json"
+
+	"github.com/deepglint/webhook/hookfile"
+)
+
+// hookSpec mirrors the subset of a hooks-file entry needed to pull out its
+// `executor` block; other fields are ignored by encoding/json.
+type hookSpec struct {
+	ID       string `json:"id"`
+	Executor *Spec  `json:"executor"`
+}
+
+// LoadHookSpecs reads the `executor` block for every hook out of the hooks
+// path, which may be a single file, a directory, or a glob pattern. It's
+// parsed independently of hook.Hooks.LoadFromFile since "executor" isn't
+// part of the upstream hook.Hook struct.
+func LoadHookSpecs(path string) (map[string]*Spec, error) {
+	specs := make(map[string]*Spec)
+
+	err := hookfile.LoadEach(path, func(file string, data []byte) error {
+		var raw []hookSpec
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+
+		for _, s := range raw {
+			if s.Executor != nil {
+				specs[s.ID] = s.Executor
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return specs, nil
+}
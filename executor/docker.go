@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"time"
+)
+
+// dockerExecutor runs the command inside a container via `docker run`,
+// piping the hook's normalized payload on stdin so the image's entrypoint
+// can consume structured data instead of shelling out to command arguments.
+type dockerExecutor struct {
+	spec *Spec
+}
+
+func newDockerExecutor(spec *Spec) Executor {
+	return &dockerExecutor{spec: spec}
+}
+
+func (e *dockerExecutor) Execute(req *Request) *Result {
+	result := &Result{StartedAt: time.Now()}
+
+	ctx := context.Background()
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	args := []string{"run", "--rm", "-i"}
+
+	for _, volume := range e.spec.Volumes {
+		args = append(args, "-v", volume)
+	}
+	for name, value := range e.spec.Env {
+		args = append(args, "-e", name+"="+value)
+	}
+
+	args = append(args, e.spec.Image, req.Command)
+	args = append(args, req.Args...)
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+
+	var stdin bytes.Buffer
+	if req.Payload != nil {
+		if data, err := json.Marshal(*req.Payload); err == nil {
+			stdin.Write(data)
+		}
+	}
+	cmd.Stdin = &stdin
+
+	out, err := cmd.Output()
+
+	result.Stdout = string(out)
+	result.Err = err
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		result.ExitCode = exitErr.ProcessState.ExitCode()
+		result.Stderr = string(exitErr.Stderr)
+	}
+
+	result.FinishedAt = time.Now()
+
+	return result
+}
@@ -0,0 +1,62 @@
+// Package executor abstracts over where a hook's command actually runs.
+// The default is a local exec.Command, matching webhook's historical
+// behavior, but a hook can opt into running inside a Docker container,
+// over SSH, or by forwarding its payload to a downstream HTTP endpoint.
+// Third parties can register additional backends with Register.
+package executor
+
+import (
+	"time"
+)
+
+// Request is the normalized input to an Executor: the command and its
+// extracted arguments, the directory to run it in, and the request data the
+// hook was triggered with.
+type Request struct {
+	HookID    string
+	Command   string
+	Args      []string
+	Directory string
+	Headers   *map[string]interface{}
+	Query     *map[string]interface{}
+	Payload   *map[string]interface{}
+	Body      *[]byte
+	Timeout   time.Duration
+}
+
+// Result is the outcome of running a hook's command, regardless of which
+// executor produced it.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Err      error
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Executor runs a hook's command somewhere and reports what happened.
+type Executor interface {
+	Execute(req *Request) *Result
+}
+
+// Spec is the `executor` block attached to a hook in the hooks file. It
+// isn't part of the upstream hook.Hook struct, so it's parsed out of the
+// same file separately by LoadHookSpecs, the same way queue.HookConfig is.
+type Spec struct {
+	Type string `json:"type"` // local, docker, ssh, http, or a registered third-party type
+
+	// docker
+	Image   string            `json:"image,omitempty"`
+	Volumes []string          `json:"volumes,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+
+	// ssh
+	Host string `json:"host,omitempty"`
+	User string `json:"user,omitempty"`
+	Key  string `json:"key,omitempty"`
+
+	// http
+	URL string `json:"url,omitempty"`
+}
@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// httpExecutor forwards the hook's normalized payload to a downstream URL
+// instead of running a local command, treating any 2xx response as success.
+type httpExecutor struct {
+	spec   *Spec
+	client *http.Client
+}
+
+func newHTTPExecutor(spec *Spec) Executor {
+	return &httpExecutor{spec: spec, client: &http.Client{}}
+}
+
+func (e *httpExecutor) Execute(req *Request) *Result {
+	result := &Result{StartedAt: time.Now()}
+
+	ctx := context.Background()
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	var body []byte
+	if req.Payload != nil {
+		body, _ = json.Marshal(*req.Payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", e.spec.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Err = err
+		result.FinishedAt = time.Now()
+		return result
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		result.Err = err
+		result.FinishedAt = time.Now()
+		return result
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := ioutil.ReadAll(resp.Body)
+
+	result.Stdout = string(respBody)
+	result.ExitCode = resp.StatusCode
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Err = fmt.Errorf("downstream %s returned %d", e.spec.URL, resp.StatusCode)
+		result.Stderr = string(respBody)
+	}
+
+	result.FinishedAt = time.Now()
+
+	return result
+}
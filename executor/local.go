@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// localExecutor runs the command with os/exec, matching webhook's
+// historical behavior. It's the default when a hook declares no executor
+// block.
+type localExecutor struct{}
+
+func newLocalExecutor(spec *Spec) Executor {
+	return &localExecutor{}
+}
+
+func (e *localExecutor) Execute(req *Request) *Result {
+	result := &Result{StartedAt: time.Now()}
+
+	ctx := context.Background()
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, req.Command, req.Args...)
+	cmd.Dir = req.Directory
+
+	out, err := cmd.Output()
+
+	result.Stdout = string(out)
+	result.Err = err
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		result.ExitCode = exitErr.ProcessState.ExitCode()
+		result.Stderr = string(exitErr.Stderr)
+	}
+
+	result.FinishedAt = time.Now()
+
+	return result
+}
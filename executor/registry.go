@@ -0,0 +1,48 @@
+package executor
+
+import "sync"
+
+// Factory builds an Executor from its configured Spec.
+type Factory func(spec *Spec) Executor
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Factory{}
+)
+
+// Register adds a constructor for an executor type, so third parties can
+// plug in new backends without modifying this package. Calling Register
+// with a type that's already registered replaces it.
+func Register(typ string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[typ] = factory
+}
+
+// For resolves the Executor for a hook's executor spec, defaulting to the
+// local executor when spec is nil or has no type set.
+func For(spec *Spec) Executor {
+	registryMu.Lock()
+	factory, ok := registry[typeOf(spec)]
+	if !ok {
+		factory = registry["local"]
+	}
+	registryMu.Unlock()
+
+	return factory(spec)
+}
+
+func typeOf(spec *Spec) string {
+	if spec == nil || spec.Type == "" {
+		return "local"
+	}
+	return spec.Type
+}
+
+func init() {
+	Register("local", newLocalExecutor)
+	Register("docker", newDockerExecutor)
+	Register("ssh", newSSHExecutor)
+	Register("http", newHTTPExecutor)
+}
@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// sshExecutor runs the command on a remote host via the system ssh
+// client, matching this package's preference for shelling out over
+// embedding a full SSH client implementation.
+type sshExecutor struct {
+	spec *Spec
+}
+
+func newSSHExecutor(spec *Spec) Executor {
+	return &sshExecutor{spec: spec}
+}
+
+func (e *sshExecutor) Execute(req *Request) *Result {
+	result := &Result{StartedAt: time.Now()}
+
+	ctx := context.Background()
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	var args []string
+	if e.spec.Key != "" {
+		args = append(args, "-i", e.spec.Key)
+	}
+
+	target := e.spec.Host
+	if e.spec.User != "" {
+		target = e.spec.User + "@" + e.spec.Host
+	}
+
+	// The ssh client itself doesn't exec these as separate argv entries on
+	// the remote end - it joins every argument after the target with a
+	// space and hands the result to the remote login shell to parse. So
+	// passing req.Command/req.Args as separate exec.Command args (safe for
+	// the local executor) would let any hook-extracted argument containing
+	// shell metacharacters run arbitrary commands on the target host.
+	// Quoting each one ourselves and joining into the single remote
+	// command string ssh actually sees closes that off.
+	remoteArgs := append([]string{req.Command}, req.Args...)
+	for i, a := range remoteArgs {
+		remoteArgs[i] = shellQuote(a)
+	}
+
+	args = append(args, target, strings.Join(remoteArgs, " "))
+
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+
+	out, err := cmd.Output()
+
+	result.Stdout = string(out)
+	result.Err = err
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		result.ExitCode = exitErr.ProcessState.ExitCode()
+		result.Stderr = string(exitErr.Stderr)
+	}
+
+	result.FinishedAt = time.Now()
+
+	return result
+}
+
+// shellQuote wraps s in single quotes so it reaches the remote login shell
+// as one literal word, escaping any single quote it already contains
+// (POSIX sh has no escape character inside single quotes, so each one has
+// to close the quoted string, contribute an escaped quote, then reopen).
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,132 @@
+// Package hookfile resolves the `-hooks` flag, which can now name a single
+// file, a directory, or a glob pattern, into the individual hook
+// definition files it covers, and normalizes each one (JSON or YAML) to
+// JSON bytes. It's shared by every package that reads the hooks file on
+// the side - main for the hook definitions themselves, and queue/executor/
+// auth for the fields layered alongside them - so all of them resolve and
+// decode hook files the same way.
+package hookfile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Resolve expands path into the hook definition files it covers: itself if
+// it names a single file, every *.json/*.yaml/*.yml file found under it
+// (recursively) if it names a directory, or every matching
+// *.json/*.yaml/*.yml file if it's a glob pattern. The result is sorted
+// for deterministic load order.
+func Resolve(path string) ([]string, error) {
+	info, statErr := os.Stat(path)
+
+	if statErr == nil && !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	if statErr == nil && info.IsDir() {
+		var files []string
+
+		err := filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !fi.IsDir() && isHookFile(p) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Strings(files)
+
+		return files, nil
+	}
+
+	// Not a plain file or directory - try path as a glob pattern.
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, statErr
+	}
+
+	var files []string
+	for _, m := range matches {
+		if fi, err := os.Stat(m); err == nil && !fi.IsDir() && isHookFile(m) {
+			files = append(files, m)
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func isHookFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadJSON reads a single hook definition file and returns its contents as
+// JSON, converting from YAML first if its extension calls for it, so
+// callers can decode every hook file the same way regardless of how it's
+// authored on disk.
+func ReadJSON(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return data, nil
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(raw)
+}
+
+// LoadEach resolves path into its hook definition files and calls fn once
+// per file, in load order, with that file's JSON bytes. It factors out the
+// resolve-then-read boilerplate shared by every package that pulls its own
+// fields (queue's max_concurrent/queue_size, executor's executor block,
+// auth's auth block, main's hook definitions themselves) out of the same
+// hook files; fn is responsible for unmarshaling those bytes into whatever
+// shape it cares about and merging the result, since that shape differs
+// per caller.
+func LoadEach(path string, fn func(file string, data []byte) error) error {
+	files, err := Resolve(path)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := ReadJSON(file)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(file, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package hookfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %+v", err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("WriteFile: %+v", err)
+	}
+
+	return path
+}
+
+func TestResolveSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "hooks.json", `[]`)
+
+	files, err := Resolve(path)
+	if err != nil {
+		t.Fatalf("Resolve: %+v", err)
+	}
+	if len(files) != 1 || files[0] != path {
+		t.Errorf("Resolve(%q) = %v, want [%q]", path, files, path)
+	}
+}
+
+func TestResolveDirectory(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.json", `[]`)
+	b := writeFile(t, dir, "sub/b.yaml", "[]\n")
+	writeFile(t, dir, "notes.txt", "ignore me")
+
+	files, err := Resolve(dir)
+	if err != nil {
+		t.Fatalf("Resolve: %+v", err)
+	}
+
+	want := []string{a, b}
+	sort.Strings(want)
+	if len(files) != len(want) {
+		t.Fatalf("Resolve(%q) = %v, want %v", dir, files, want)
+	}
+	for i, f := range files {
+		if f != want[i] {
+			t.Errorf("Resolve(%q)[%d] = %q, want %q", dir, i, f, want[i])
+		}
+	}
+}
+
+func TestResolveGlob(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.json", `[]`)
+	writeFile(t, dir, "b.txt", "ignore me")
+
+	files, err := Resolve(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("Resolve: %+v", err)
+	}
+	if len(files) != 1 || files[0] != a {
+		t.Errorf("Resolve glob = %v, want [%q]", files, a)
+	}
+}
+
+func TestReadJSONConvertsYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "hooks.yaml", "- id: deploy\n  execute-command: /bin/true\n")
+
+	data, err := ReadJSON(path)
+	if err != nil {
+		t.Fatalf("ReadJSON: %+v", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("decoding ReadJSON output: %+v", err)
+	}
+	if len(decoded) != 1 || decoded[0]["id"] != "deploy" {
+		t.Errorf("ReadJSON(%q) decoded to %v, want a single hook with id=deploy", path, decoded)
+	}
+}
+
+func TestLoadEachVisitsEveryFileInOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", `[{"id":"a"}]`)
+	writeFile(t, dir, "b.json", `[{"id":"b"}]`)
+
+	var seen []string
+	err := LoadEach(dir, func(file string, data []byte) error {
+		seen = append(seen, file)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("LoadEach: %+v", err)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("LoadEach visited %d files, want 2", len(seen))
+	}
+	if want := filepath.Join(dir, "a.json"); seen[0] != want {
+		t.Errorf("LoadEach visited %q first, want %q", seen[0], want)
+	}
+	if want := filepath.Join(dir, "b.json"); seen[1] != want {
+		t.Errorf("LoadEach visited %q second, want %q", seen[1], want)
+	}
+}
+
+func TestLoadEachPropagatesCallbackError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", `[]`)
+
+	wantErr := os.ErrInvalid
+	err := LoadEach(dir, func(file string, data []byte) error {
+		return wantErr
+	})
+	if err != wantErr {
+		t.Errorf("LoadEach returned %v, want %v", err, wantErr)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/adnanh/webhook/hook"
+
+	"github.com/deepglint/webhook/hookfile"
+)
+
+// loadHooks loads hook definitions from path, which may be a single file,
+// a directory, or a glob pattern. A directory is scanned recursively for
+// *.json and *.yaml/*.yml files, which are parsed and merged into one
+// hook.Hooks, rejecting any hook ID defined more than once across the
+// merged files.
+func loadHooks(path string) (hook.Hooks, error) {
+	merged := hook.Hooks{}
+	definedIn := make(map[string]string)
+
+	err := hookfile.LoadEach(path, func(file string, data []byte) error {
+		var fileHooks hook.Hooks
+		if err := json.Unmarshal(data, &fileHooks); err != nil {
+			return fmt.Errorf("%s: %+v", file, err)
+		}
+
+		for _, h := range fileHooks {
+			if existing, ok := definedIn[h.ID]; ok {
+				return fmt.Errorf("hook %q in %s is already defined in %s", h.ID, file, existing)
+			}
+
+			definedIn[h.ID] = file
+			merged = append(merged, h)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
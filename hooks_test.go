@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeHooksFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("WriteFile: %+v", err)
+	}
+
+	return path
+}
+
+func TestLoadHooksMergesFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeHooksFile(t, dir, "a.json", `[{"id":"a","execute-command":"/bin/true"}]`)
+	writeHooksFile(t, dir, "b.json", `[{"id":"b","execute-command":"/bin/true"}]`)
+
+	hooks, err := loadHooks(dir)
+	if err != nil {
+		t.Fatalf("loadHooks: %+v", err)
+	}
+	if len(hooks) != 2 {
+		t.Fatalf("loadHooks returned %d hooks, want 2", len(hooks))
+	}
+}
+
+func TestLoadHooksRejectsDuplicateID(t *testing.T) {
+	dir := t.TempDir()
+	writeHooksFile(t, dir, "a.json", `[{"id":"dup","execute-command":"/bin/true"}]`)
+	writeHooksFile(t, dir, "b.json", `[{"id":"dup","execute-command":"/bin/true"}]`)
+
+	if _, err := loadHooks(dir); err == nil {
+		t.Error("loadHooks returned nil error for a hook ID defined in two files, want an error")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/codegangsta/negroni"
+)
+
+type contextKey string
+
+const accessLogContextKey contextKey = "accessLog"
+
+// accessLogEntry carries the per-request fields that are only known once
+// hookHandler has processed the request (which hook matched, whether its
+// trigger rule fired, and - for synchronous hooks - the exit code), so the
+// access logger, which wraps the whole request as outer middleware, can
+// include them in its log line.
+type accessLogEntry struct {
+	HookID      string
+	Matched     bool
+	ExitCode    int
+	HasExitCode bool
+}
+
+// withAccessLogEntry attaches a fresh accessLogEntry to the request context
+// and returns both, so downstream handlers can fill it in.
+func withAccessLogEntry(r *http.Request) (*http.Request, *accessLogEntry) {
+	entry := &accessLogEntry{}
+	return r.WithContext(context.WithValue(r.Context(), accessLogContextKey, entry)), entry
+}
+
+func accessLogEntryFromContext(ctx context.Context) *accessLogEntry {
+	if entry, ok := ctx.Value(accessLogContextKey).(*accessLogEntry); ok {
+		return entry
+	}
+	return &accessLogEntry{}
+}
+
+// jsonAccessLogLine is the shape of one -log-format=json access log line.
+type jsonAccessLogLine struct {
+	Time       string  `json:"time"`
+	HookID     string  `json:"hook_id,omitempty"`
+	RemoteAddr string  `json:"remote_addr"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+	Matched    bool    `json:"matched"`
+	ExitCode   *int    `json:"exit_code,omitempty"`
+}
+
+// jsonAccessLogger is a negroni.Handler alternative to negroni's built-in
+// plain-text logger, writing one JSON object per request. Selected with
+// -log-format=json.
+type jsonAccessLogger struct {
+	logger *log.Logger
+}
+
+func newJSONAccessLogger(logger *log.Logger) *jsonAccessLogger {
+	return &jsonAccessLogger{logger: logger}
+}
+
+func (l *jsonAccessLogger) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := time.Now()
+
+	r, entry := withAccessLogEntry(r)
+
+	rw := w.(negroni.ResponseWriter)
+
+	next(rw, r)
+
+	line := jsonAccessLogLine{
+		Time:       start.UTC().Format(time.RFC3339),
+		HookID:     entry.HookID,
+		RemoteAddr: r.RemoteAddr,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Status:     rw.Status(),
+		DurationMs: float64(time.Since(start)) / float64(time.Millisecond),
+		Matched:    entry.Matched,
+	}
+
+	if entry.HasExitCode {
+		exitCode := entry.ExitCode
+		line.ExitCode = &exitCode
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		l.logger.Printf("error marshaling access log entry: %+v\n", err)
+		return
+	}
+
+	l.logger.Println(string(data))
+}
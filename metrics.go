@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/deepglint/webhook/queue"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_requests_total",
+		Help: "Total number of hook requests, labeled by hook id and result.",
+	}, []string{"id", "result"})
+
+	commandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "webhook_command_duration_seconds",
+		Help: "Duration of hook command executions in seconds.",
+	}, []string{"id"})
+
+	commandExitCode = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "webhook_command_exit_code",
+		Help: "Exit code of the most recently finished hook command.",
+	}, []string{"id"})
+
+	queueDepthGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "webhook_queue_depth",
+		Help: "Number of jobs currently waiting in a hook's queue.",
+	}, []string{"id"})
+
+	reloadsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_reloads_total",
+		Help: "Total number of hook file reloads.",
+	})
+)
+
+// watchQueueDepths periodically samples the queue manager's depths into
+// the webhook_queue_depth gauge, since the queue package doesn't import
+// prometheus itself.
+func watchQueueDepths(m *queue.Manager) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for id, depth := range m.QueueDepths() {
+			queueDepthGauge.WithLabelValues(id).Set(float64(depth))
+		}
+	}
+}
+
+func metricsHandler() http.Handler {
+	return promhttp.Handler()
+}
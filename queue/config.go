@@ -0,0 +1,35 @@
+package queue
+
+import (
+	"encoding/json"
+
+	"github.com/deepglint/webhook/hookfile"
+)
+
+// LoadHookConfigs reads the queue-related fields (max_concurrent,
+// queue_size, command_timeout, async) for every hook out of the hooks
+// path, which may be a single file, a directory, or a glob pattern. It's
+// parsed independently of hook.Hooks.LoadFromFile since those fields
+// aren't part of the upstream hook.Hook struct; unrecognized fields are
+// simply ignored by encoding/json, so this can read the very same files.
+func LoadHookConfigs(path string) (map[string]HookConfig, error) {
+	configs := make(map[string]HookConfig)
+
+	err := hookfile.LoadEach(path, func(file string, data []byte) error {
+		var raw []HookConfig
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+
+		for _, cfg := range raw {
+			configs[cfg.ID] = cfg
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
@@ -0,0 +1,358 @@
+// Package queue provides a bounded, per-hook worker pool so that bursts of
+// incoming triggers don't fork an unbounded number of processes. Each hook
+// gets its own queue and pool of workers sized from its configuration (or
+// the manager's default), and jobs snapshot the request state so they can
+// be executed independently of the originating HTTP request.
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/adnanh/webhook/hook"
+
+	"github.com/deepglint/webhook/executor"
+)
+
+// HookConfig holds the queue-related settings for a single hook. These
+// fields live alongside a hook's definition in the hooks file but aren't
+// part of the hook package's own Hook struct, so they're parsed separately
+// by LoadHookConfigs.
+type HookConfig struct {
+	ID             string `json:"id"`
+	MaxConcurrent  int    `json:"max_concurrent"`
+	QueueSize      int    `json:"queue_size"`
+	CommandTimeout int    `json:"command_timeout"` // seconds
+	Async          *bool  `json:"async"`
+}
+
+// IsAsync reports whether the hook should respond immediately (the
+// default) instead of blocking for the job result.
+func (c HookConfig) IsAsync() bool {
+	return c.Async == nil || *c.Async
+}
+
+// Job is a single enqueued invocation of a hook's command, with all of the
+// request-derived inputs snapshotted so it can run independently of the
+// HTTP request that triggered it.
+type Job struct {
+	Hook    *hook.Hook
+	Headers *map[string]interface{}
+	Query   *map[string]interface{}
+	Payload *map[string]interface{}
+	Body    *[]byte
+
+	Done chan *Result
+}
+
+// Result is the outcome of running a Job's command.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Err      error
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// OnComplete, if set, is called with every job's result once it finishes
+// executing, so callers (e.g. the admin API's run history) can observe
+// jobs without the queue package depending on them.
+type OnComplete func(job *Job, result *Result)
+
+// Manager owns one pool per hook ID.
+type Manager struct {
+	defaultMaxWorkers int
+	onComplete        OnComplete
+
+	mu    sync.Mutex
+	pools map[string]*pool
+}
+
+// NewManager creates a Manager that falls back to defaultMaxWorkers
+// concurrent workers for any hook that doesn't set max_concurrent.
+func NewManager(defaultMaxWorkers int, onComplete OnComplete) *Manager {
+	return &Manager{
+		defaultMaxWorkers: defaultMaxWorkers,
+		onComplete:        onComplete,
+		pools:             make(map[string]*pool),
+	}
+}
+
+// Configure (re)builds the worker pools to match the given hooks and their
+// queue configs, starting pools for new hooks and leaving existing ones
+// that are unchanged running. specs carries each hook's executor block, if
+// any; a hook with no entry runs its command locally, matching webhook's
+// historical behavior. It should be called once at startup and again after
+// every hook reload.
+func (m *Manager) Configure(hooks hook.Hooks, configs map[string]HookConfig, specs map[string]*executor.Spec) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool)
+
+	for _, h := range hooks {
+		cfg := configs[h.ID]
+		cfg.ID = h.ID
+
+		if cfg.MaxConcurrent <= 0 {
+			cfg.MaxConcurrent = m.defaultMaxWorkers
+		}
+		if cfg.QueueSize <= 0 {
+			cfg.QueueSize = cfg.MaxConcurrent
+		}
+
+		spec := specs[h.ID]
+
+		seen[h.ID] = true
+
+		if p, ok := m.pools[h.ID]; ok {
+			oldCfg, _ := p.config()
+
+			if oldCfg.MaxConcurrent == cfg.MaxConcurrent && oldCfg.QueueSize == cfg.QueueSize {
+				p.reconfigure(cfg, spec)
+				continue
+			}
+
+			// max_concurrent/queue_size changed: the worker count and
+			// channel capacity are fixed at newPool time, so reconfigure
+			// alone can't apply them. Drain the old pool and swap in a
+			// freshly sized one instead of leaving the hook stuck at
+			// whatever limits it first loaded with.
+			p.stop()
+		}
+
+		p := newPool(cfg, spec, m.onComplete)
+		m.pools[h.ID] = p
+	}
+
+	for id, p := range m.pools {
+		if !seen[id] {
+			p.stop()
+			delete(m.pools, id)
+		}
+	}
+}
+
+// Submit enqueues job on its hook's pool. It returns false if the pool is
+// at capacity, has been stopped, or - in the brief window between a hook
+// reload swapping in new hooks and configureQueue finishing - doesn't
+// exist yet. The no-pool case used to spin up an ad-hoc unbounded pool,
+// but that pool was never tracked in m.pools, so Shutdown could never
+// find and drain it and every such race leaked a worker goroutine;
+// rejecting the request is the same trade-off the full-queue case already
+// makes.
+func (m *Manager) Submit(job *Job) bool {
+	m.mu.Lock()
+	p, ok := m.pools[job.Hook.ID]
+	m.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	return p.submit(job)
+}
+
+// QueueDepths returns the number of jobs currently waiting (not yet
+// picked up by a worker) for every configured hook, for metrics reporting.
+func (m *Manager) QueueDepths() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	depths := make(map[string]int, len(m.pools))
+	for id, p := range m.pools {
+		depths[id] = len(p.jobs)
+	}
+
+	return depths
+}
+
+// ConfigFor returns the queue configuration for a hook, or the manager's
+// defaults if none was loaded from the hooks file.
+func (m *Manager) ConfigFor(id string) HookConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p, ok := m.pools[id]; ok {
+		cfg, _ := p.config()
+		return cfg
+	}
+
+	return HookConfig{ID: id, MaxConcurrent: m.defaultMaxWorkers, QueueSize: m.defaultMaxWorkers}
+}
+
+// Shutdown stops accepting new jobs and waits for in-flight jobs to drain,
+// or for ctx to be done, whichever comes first.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	pools := make([]*pool, 0, len(m.pools))
+	for _, p := range m.pools {
+		pools = append(pools, p)
+	}
+	m.mu.Unlock()
+
+	for _, p := range pools {
+		p.stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for _, p := range pools {
+			p.wg.Wait()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pool is the bounded queue and worker goroutines for a single hook. mu
+// guards both the cfg/spec fields (written by reconfigure, read by every
+// worker) and the closed flag (checked by submit, set by stop): submit and
+// stop take the same lock so a send on p.jobs can never race its close.
+type pool struct {
+	jobs       chan *Job
+	onComplete OnComplete
+	wg         sync.WaitGroup
+
+	mu     sync.RWMutex
+	cfg    HookConfig
+	spec   *executor.Spec
+	closed bool
+}
+
+func newPool(cfg HookConfig, spec *executor.Spec, onComplete OnComplete) *pool {
+	p := &pool{
+		cfg:        cfg,
+		spec:       spec,
+		jobs:       make(chan *Job, cfg.QueueSize),
+		onComplete: onComplete,
+	}
+
+	for i := 0; i < cfg.MaxConcurrent; i++ {
+		p.wg.Add(1)
+		go p.worker()
+	}
+
+	return p
+}
+
+// reconfigure updates the pool's recorded config and executor spec.
+// Resizing a running channel isn't possible, so reconfigure doesn't apply
+// a change to max_concurrent/queue_size; Configure replaces the pool
+// instead when either of them changes.
+func (p *pool) reconfigure(cfg HookConfig, spec *executor.Spec) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cfg = cfg
+	p.spec = spec
+}
+
+// config returns a consistent snapshot of the pool's current HookConfig
+// and executor.Spec for a worker to run a job with.
+func (p *pool) config() (HookConfig, *executor.Spec) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.cfg, p.spec
+}
+
+// submit enqueues job, returning false if the pool is at capacity or has
+// already been stopped. It holds the same lock stop() sets closed under,
+// and stop() only closes p.jobs after that, so this send and that close
+// can never race.
+func (p *pool) submit(job *Job) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop marks the pool closed and closes p.jobs, causing its workers to
+// drain whatever's already queued and exit. Taking the write lock first
+// ensures no submit() is concurrently sending when the channel closes.
+func (p *pool) stop() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	close(p.jobs)
+}
+
+func (p *pool) worker() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		cfg, spec := p.config()
+
+		result := run(job, cfg, spec)
+
+		if p.onComplete != nil {
+			p.onComplete(job, result)
+		}
+
+		if job.Done != nil {
+			job.Done <- result
+		}
+	}
+}
+
+// run resolves the job's executor (local by default, or whatever spec
+// declares) and runs the job's command through it, enforcing
+// cfg.CommandTimeout if set.
+func run(job *Job, cfg HookConfig, spec *executor.Spec) *Result {
+	args := job.Hook.ExtractCommandArguments(job.Headers, job.Query, job.Payload)
+
+	var cmdArgs []string
+	if len(args) > 1 {
+		cmdArgs = args[1:]
+	}
+
+	req := &executor.Request{
+		HookID:    job.Hook.ID,
+		Command:   job.Hook.ExecuteCommand,
+		Args:      cmdArgs,
+		Directory: job.Hook.CommandWorkingDirectory,
+		Headers:   job.Headers,
+		Query:     job.Query,
+		Payload:   job.Payload,
+		Body:      job.Body,
+	}
+
+	if cfg.CommandTimeout > 0 {
+		req.Timeout = time.Duration(cfg.CommandTimeout) * time.Second
+	}
+
+	out := executor.For(spec).Execute(req)
+
+	return &Result{
+		Stdout:     out.Stdout,
+		Stderr:     out.Stderr,
+		ExitCode:   out.ExitCode,
+		Err:        out.Err,
+		StartedAt:  out.StartedAt,
+		FinishedAt: out.FinishedAt,
+	}
+}
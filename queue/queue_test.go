@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/adnanh/webhook/hook"
+)
+
+func testJob(id string) *Job {
+	return &Job{
+		Hook: &hook.Hook{ID: id, ExecuteCommand: "/bin/true"},
+		Done: make(chan *Result, 1),
+	}
+}
+
+func TestPoolSubmitAfterStop(t *testing.T) {
+	p := newPool(HookConfig{ID: "h1", MaxConcurrent: 1, QueueSize: 1}, nil, nil)
+	p.stop()
+
+	if p.submit(testJob("h1")) {
+		t.Error("submit returned true after stop, want false")
+	}
+}
+
+func TestPoolStopIsIdempotent(t *testing.T) {
+	p := newPool(HookConfig{ID: "h1", MaxConcurrent: 1, QueueSize: 1}, nil, nil)
+
+	p.stop()
+	p.stop() // must not panic on a double close of p.jobs
+}
+
+// TestPoolSubmitStopRace exercises the lock that's supposed to make a
+// concurrent submit/stop safe: every submit either succeeds before stop
+// closes the channel or observes closed and bails out, but a send on a
+// closed channel (a panic) must never happen.
+func TestPoolSubmitStopRace(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		p := newPool(HookConfig{ID: "h1", MaxConcurrent: 2, QueueSize: 4}, nil, nil)
+
+		var wg sync.WaitGroup
+		for j := 0; j < 8; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.submit(testJob("h1"))
+			}()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.stop()
+		}()
+
+		wg.Wait()
+	}
+}
+
+func TestManagerConfigureRecreatesPoolOnSizeChange(t *testing.T) {
+	m := NewManager(1, nil)
+	hooks := hook.Hooks{hook.Hook{ID: "h1"}}
+
+	m.Configure(hooks, map[string]HookConfig{"h1": {MaxConcurrent: 1, QueueSize: 1}}, nil)
+	before := m.pools["h1"]
+
+	// Same limits: reconfigure in place, same pool/channel.
+	m.Configure(hooks, map[string]HookConfig{"h1": {MaxConcurrent: 1, QueueSize: 1}}, nil)
+	if m.pools["h1"] != before {
+		t.Error("Configure recreated the pool when max_concurrent/queue_size didn't change")
+	}
+
+	// Different queue_size: the old pool must be stopped and swapped for a
+	// freshly sized one, since its channel capacity can't change in place.
+	m.Configure(hooks, map[string]HookConfig{"h1": {MaxConcurrent: 1, QueueSize: 4}}, nil)
+	after := m.pools["h1"]
+
+	if after == before {
+		t.Error("Configure reused the pool after queue_size changed, want a new one")
+	}
+
+	before.mu.RLock()
+	closed := before.closed
+	before.mu.RUnlock()
+	if !closed {
+		t.Error("old pool was not stopped after being replaced")
+	}
+
+	if cap(after.jobs) != 4 {
+		t.Errorf("new pool's jobs channel has capacity %d, want 4", cap(after.jobs))
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -9,13 +10,19 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/adnanh/webhook/hook"
 
+	"github.com/deepglint/webhook/auth"
+	"github.com/deepglint/webhook/executor"
+	"github.com/deepglint/webhook/hookfile"
+	"github.com/deepglint/webhook/queue"
+
 	"github.com/codegangsta/negroni"
 	"github.com/gorilla/mux"
 
@@ -31,16 +38,28 @@ var (
 	port           = flag.Int("port", 9000, "port the webhook should serve hooks on")
 	verbose        = flag.Bool("verbose", false, "show verbose output")
 	hotReload      = flag.Bool("hotreload", false, "watch hooks file for changes and reload them automatically")
-	hooksFilePath  = flag.String("hooks", "hooks.json", "path to the json file containing defined hooks the webhook should serve")
+	hooksFilePath  = flag.String("hooks", "hooks.json", "path to the json/yaml file, directory, or glob pattern containing defined hooks the webhook should serve")
 	hooksURLPrefix = flag.String("urlprefix", "hooks", "url prefix to use for served hooks (protocol://yourserver:port/PREFIX/:hook-id)")
 	secure         = flag.Bool("secure", false, "use HTTPS instead of HTTP")
 	cert           = flag.String("cert", "cert.pem", "path to the HTTPS certificate pem file")
 	key            = flag.String("key", "key.pem", "path to the HTTPS certificate private key pem file")
 
+	historySize = flag.Int("history", 100, "number of recent runs to keep per hook for the admin API (0 disables history)")
+	adminToken  = flag.String("admin-token", "", "bearer token required to access the admin API (disabled if empty)")
+	adminListen = flag.String("admin-listen", "", "separate ip:port to serve the admin API on (served under /admin on the main listener if empty)")
+
+	maxWorkers = flag.Int("max-workers", 4, "default number of concurrent workers per hook, unless overridden by the hook's max_concurrent")
+
+	logFormat      = flag.String("log-format", "text", "access log format: text or json")
+	metricsEnabled = flag.Bool("metrics", false, "expose a /metrics Prometheus endpoint")
+
 	watcher *fsnotify.Watcher
 	signals chan os.Signal
 
-	hooks hook.Hooks
+	hooks        hook.Hooks
+	history      *runHistory
+	queueManager *queue.Manager
+	authSpecs    map[string]*auth.Spec
 )
 
 func init() {
@@ -48,6 +67,9 @@ func init() {
 
 	flag.Parse()
 
+	history = newRunHistory(*historySize)
+	queueManager = queue.NewManager(*maxWorkers, recordQueueResult)
+
 	log.SetPrefix("[webhook] ")
 	log.SetFlags(log.Ldate | log.Ltime)
 
@@ -61,23 +83,28 @@ func init() {
 	log.Printf("setting up os signal watcher\n")
 
 	signals = make(chan os.Signal, 1)
-	signal.Notify(signals, syscall.SIGUSR1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGTERM)
 
 	go watchForSignals()
 
 	// load and parse hooks
 	log.Printf("attempting to load hooks from %s\n", *hooksFilePath)
 
-	err := hooks.LoadFromFile(*hooksFilePath)
+	loaded, err := loadHooks(*hooksFilePath)
 
 	if err != nil {
 		log.Printf("couldn't load hooks from file! %+v\n", err)
 	} else {
+		hooks = loaded
+
 		log.Printf("loaded %d hook(s) from file\n", len(hooks))
 
 		for _, hook := range hooks {
 			log.Printf("\t> %s\n", hook.ID)
 		}
+
+		configureQueue()
+		configureAuth()
 	}
 }
 
@@ -97,22 +124,27 @@ func main() {
 
 		go watchForFileChange()
 
-		err = watcher.Add(*hooksFilePath)
-		if err != nil {
-			log.Fatal("error adding hooks file to the watcher", err)
-		}
+		registerWatches(*hooksFilePath)
 	}
 
-	l := negroni.NewLogger()
-	l.Logger = log.New(os.Stdout, "[webhook] ", log.Ldate|log.Ltime)
+	accessLogger := log.New(os.Stdout, "[webhook] ", log.Ldate|log.Ltime)
 
 	negroniRecovery := &negroni.Recovery{
-		Logger:     l.Logger,
+		Logger:     accessLogger,
 		PrintStack: true,
 		StackAll:   false,
 		StackSize:  1024 * 8,
 	}
 
+	var l negroni.Handler
+	if *logFormat == "json" {
+		l = newJSONAccessLogger(accessLogger)
+	} else {
+		textLogger := negroni.NewLogger()
+		textLogger.Logger = accessLogger
+		l = textLogger
+	}
+
 	n := negroni.New(negroniRecovery, l)
 
 	router := mux.NewRouter()
@@ -127,6 +159,28 @@ func main() {
 
 	router.HandleFunc(hooksURL, hookHandler)
 
+	if *metricsEnabled {
+		go watchQueueDepths(queueManager)
+	}
+
+	if *adminListen != "" {
+		adminRouter := newAdminRouter()
+		if *metricsEnabled {
+			adminRouter.Handle("/metrics", metricsHandler())
+		}
+
+		log.Printf("starting admin API on %s\n", *adminListen)
+		go func() {
+			log.Fatal(http.ListenAndServe(*adminListen, adminRouter))
+		}()
+	} else {
+		router.PathPrefix("/admin").Handler(newAdminRouter())
+
+		if *metricsEnabled {
+			router.Handle("/metrics", metricsHandler())
+		}
+	}
+
 	n.UseHandler(router)
 
 	if *secure {
@@ -152,6 +206,10 @@ func hookHandler(w http.ResponseWriter, r *http.Request) {
 			log.Printf("error reading the request body. %+v\n", err)
 		}
 
+		if !verifyRequest(w, id, r, body) {
+			return
+		}
+
 		// parse headers
 		headers := valuesToMap(r.Header)
 
@@ -183,66 +241,216 @@ func hookHandler(w http.ResponseWriter, r *http.Request) {
 
 		hook.ParseJSONParameters(&headers, &query, &payload)
 
-		// handle hook
-		go handleHook(hook, &headers, &query, &payload, &body)
-
-		// send the hook defined response message
-		fmt.Fprintf(w, hook.ResponseMessage)
+		handleHook(w, r, hook, &headers, &query, &payload, &body)
 	} else {
 		w.WriteHeader(http.StatusNotFound)
 		fmt.Fprintf(w, "Hook not found.")
 	}
 }
 
-func handleHook(hook *hook.Hook, headers, query, payload *map[string]interface{}, body *[]byte) {
-	if hook.TriggerRule == nil || hook.TriggerRule != nil && hook.TriggerRule.Evaluate(headers, query, payload, body) {
-		log.Printf("%s hook triggered successfully\n", hook.ID)
+// verifyRequest checks the hook's configured auth block, if any, against
+// the raw request headers and body, writing a 401 and returning false if
+// verification fails. A hook with no auth block configured always passes.
+func verifyRequest(w http.ResponseWriter, id string, r *http.Request, body []byte) bool {
+	verifier, err := auth.For(authSpecs[id])
+	if err != nil {
+		log.Printf("%s has a misconfigured auth block: %+v\n", id, err)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, "Verification failed.")
+		return false
+	}
 
-		cmd := exec.Command(hook.ExecuteCommand)
-		cmd.Args = hook.ExtractCommandArguments(headers, query, payload)
-		cmd.Dir = hook.CommandWorkingDirectory
+	if verifier == nil {
+		return true
+	}
 
-		log.Printf("executing %s (%s) with arguments %s using %s as cwd\n", hook.ExecuteCommand, cmd.Path, cmd.Args, cmd.Dir)
+	if err := verifier.Verify(&auth.Request{Headers: r.Header, Body: body, RemoteAddr: r.RemoteAddr}); err != nil {
+		log.Printf("%s failed request verification: %+v\n", id, err)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, "Verification failed.")
+		return false
+	}
 
-		out, err := cmd.Output()
+	return true
+}
 
-		log.Printf("stdout: %s\n", out)
+// handleHook decides whether the hook's trigger rule matched and, if so,
+// enqueues it on the hook's worker pool. A hook configured with
+// `"async": false` blocks until the job finishes and returns its stdout
+// as the HTTP response body and its exit code in the X-Exit-Code header;
+// otherwise the hook's configured response message is sent immediately.
+func handleHook(w http.ResponseWriter, r *http.Request, hook *hook.Hook, headers, query, payload *map[string]interface{}, body *[]byte) {
+	entry := accessLogEntryFromContext(r.Context())
+	entry.HookID = hook.ID
 
-		if err != nil {
-			log.Printf("stderr: %+v\n", err)
-		}
-		log.Printf("finished handling %s\n", hook.ID)
-	} else {
+	matched := hook.TriggerRule == nil || hook.TriggerRule != nil && hook.TriggerRule.Evaluate(headers, query, payload, body)
+	entry.Matched = matched
+
+	if !matched {
 		log.Printf("%s hook did not get triggered\n", hook.ID)
+
+		requestsTotal.WithLabelValues(hook.ID, "unmatched").Inc()
+
+		history.add(&RunRecord{
+			HookID:     hook.ID,
+			Trigger:    triggerDescription(hook.TriggerRule),
+			Matched:    false,
+			Status:     "unmatched",
+			StartedAt:  time.Now(),
+			FinishedAt: time.Now(),
+		})
+
+		fmt.Fprintf(w, hook.ResponseMessage)
+		return
+	}
+
+	log.Printf("%s hook triggered successfully\n", hook.ID)
+
+	cfg := queueManager.ConfigFor(hook.ID)
+
+	job := &queue.Job{
+		Hook:    hook,
+		Headers: headers,
+		Query:   query,
+		Payload: payload,
+		Body:    body,
+	}
+
+	if !cfg.IsAsync() {
+		job.Done = make(chan *queue.Result, 1)
+	}
+
+	if !queueManager.Submit(job) {
+		log.Printf("%s queue is full, rejecting request\n", hook.ID)
+		requestsTotal.WithLabelValues(hook.ID, "rejected").Inc()
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, "Queue full.")
+		return
+	}
+
+	if job.Done == nil {
+		fmt.Fprintf(w, hook.ResponseMessage)
+		return
 	}
+
+	result := <-job.Done
+
+	entry.ExitCode = result.ExitCode
+	entry.HasExitCode = true
+
+	w.Header().Set("X-Exit-Code", fmt.Sprint(result.ExitCode))
+	if result.Err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	fmt.Fprintf(w, "%s", result.Stdout)
 }
 
-func reloadHooks() {
-	newHooks := hook.Hooks{}
+// triggerDescription renders a hook's trigger rule for the admin API's run
+// history, so an operator looking at /admin/hooks/{id}/runs can see what a
+// run actually matched against. A hook with no trigger rule always
+// matches, so there's nothing to describe.
+func triggerDescription(rule *hook.Rules) string {
+	if rule == nil {
+		return ""
+	}
 
+	return fmt.Sprintf("%+v", rule)
+}
+
+// recordQueueResult turns a finished queue.Job/Result pair into a
+// RunRecord for the admin API's run history and reports it to Prometheus.
+func recordQueueResult(job *queue.Job, result *queue.Result) {
+	status := "success"
+	if result.Err != nil {
+		status = "error"
+	}
+
+	requestsTotal.WithLabelValues(job.Hook.ID, status).Inc()
+	commandDuration.WithLabelValues(job.Hook.ID).Observe(result.FinishedAt.Sub(result.StartedAt).Seconds())
+	commandExitCode.WithLabelValues(job.Hook.ID).Set(float64(result.ExitCode))
+
+	history.add(&RunRecord{
+		HookID:      job.Hook.ID,
+		Trigger:     triggerDescription(job.Hook.TriggerRule),
+		Matched:     true,
+		Status:      status,
+		ExitCode:    result.ExitCode,
+		Stdout:      result.Stdout,
+		Stderr:      result.Stderr,
+		StartedAt:   result.StartedAt,
+		FinishedAt:  result.FinishedAt,
+		DurationSec: result.FinishedAt.Sub(result.StartedAt).Seconds(),
+	})
+
+	log.Printf("finished handling %s\n", job.Hook.ID)
+}
+
+func reloadHooks() {
 	// parse and swap
 	log.Printf("attempting to reload hooks from %s\n", *hooksFilePath)
 
-	err := newHooks.LoadFromFile(*hooksFilePath)
+	newHooks, err := loadHooks(*hooksFilePath)
 
 	if err != nil {
 		log.Printf("couldn't load hooks from file! %+v\n", err)
 	} else {
-		log.Printf("loaded %d hook(s) from file\n", len(hooks))
+		log.Printf("loaded %d hook(s) from file\n", len(newHooks))
 
-		for _, hook := range hooks {
+		for _, hook := range newHooks {
 			log.Printf("\t> %s\n", hook.ID)
 		}
 
 		hooks = newHooks
+
+		configureQueue()
+		configureAuth()
+
+		reloadsTotal.Inc()
+	}
+}
+
+// configureQueue (re)builds the queue manager's worker pools from the
+// currently loaded hooks and their queue and executor settings.
+func configureQueue() {
+	configs, err := queue.LoadHookConfigs(*hooksFilePath)
+	if err != nil {
+		log.Printf("couldn't load queue config from hooks file! %+v\n", err)
+		configs = map[string]queue.HookConfig{}
+	}
+
+	specs, err := executor.LoadHookSpecs(*hooksFilePath)
+	if err != nil {
+		log.Printf("couldn't load executor config from hooks file! %+v\n", err)
+		specs = map[string]*executor.Spec{}
 	}
+
+	queueManager.Configure(hooks, configs, specs)
+}
+
+// configureAuth (re)loads each hook's `auth` block from the hooks file, so
+// hookHandler can verify a request's provider signature before the hook's
+// trigger rule is ever evaluated.
+func configureAuth() {
+	specs, err := auth.LoadHookSpecs(*hooksFilePath)
+	if err != nil {
+		log.Printf("couldn't load auth config from hooks file! %+v\n", err)
+		specs = map[string]*auth.Spec{}
+	}
+
+	authSpecs = specs
 }
 
 func watchForFileChange() {
 	for {
 		select {
 		case event := <-(*watcher).Events:
-			if event.Op&fsnotify.Write == fsnotify.Write {
+			switch {
+			case event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0:
+				log.Printf("%s appeared or disappeared, rescanning hooks path\n", event.Name)
+
+				registerWatches(*hooksFilePath)
+				reloadHooks()
+			case event.Op&fsnotify.Write == fsnotify.Write:
 				log.Println("hooks file modified")
 
 				reloadHooks()
@@ -253,6 +461,43 @@ func watchForFileChange() {
 	}
 }
 
+// registerWatches adds every directory and hook definition file under path
+// to the fsnotify watcher, so a hot-reloaded hooks directory picks up
+// edits to existing files as well as new files being dropped in or
+// removed. It's called once at startup and again on every Create/Remove
+// event, since fsnotify isn't recursive and can't watch files that don't
+// exist yet.
+func registerWatches(path string) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		err := filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				if err := watcher.Add(p); err != nil {
+					log.Printf("error watching directory %s: %+v\n", p, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("error walking hooks directory %s: %+v\n", path, err)
+		}
+	}
+
+	files, err := hookfile.Resolve(path)
+	if err != nil {
+		log.Printf("error resolving hook files to watch: %+v\n", err)
+		return
+	}
+
+	for _, file := range files {
+		if err := watcher.Add(file); err != nil {
+			log.Printf("error watching %s: %+v\n", file, err)
+		}
+	}
+}
+
 func watchForSignals() {
 	log.Println("os signal watcher ready")
 
@@ -262,6 +507,16 @@ func watchForSignals() {
 			log.Println("caught USR1 signal")
 
 			reloadHooks()
+		} else if sig == syscall.SIGTERM {
+			log.Println("caught TERM signal, draining in-flight jobs")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := queueManager.Shutdown(ctx); err != nil {
+				log.Printf("queue did not drain cleanly: %+v\n", err)
+			}
+			cancel()
+
+			os.Exit(0)
 		} else {
 			log.Printf("caught unhandled signal %+v\n", sig)
 		}